@@ -0,0 +1,206 @@
+// Package archive implements a seekable, compacted container for golog's
+// older history. A compact run moves events out of the hot ~/.golog CSV
+// into one archive file per month; status/list can still fold archived
+// totals into a --since/--until report without decompressing a whole
+// year's worth of history to do it.
+//
+// Layout: zero or more zstd frames, each holding about frameSize bytes of
+// *uncompressed* CSV rows (a frame is closed once it reaches frameSize, so
+// it can run somewhat past it), followed by a trailer - a JSON index of every
+// frame's offset, length and first/last timestamp, then an 8-byte
+// little-endian length of that index so a reader can find it by seeking
+// from the end of the file.
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// frameSize is the target uncompressed size, in bytes, of each zstd frame.
+// The last frame in a file is usually smaller.
+const frameSize = 128 * 1024
+
+// frameEntry indexes a single zstd frame within the archive file
+type frameEntry struct {
+	Offset int64     `json:"offset"`
+	Length int64     `json:"length"`
+	First  time.Time `json:"first"`
+	Last   time.Time `json:"last"`
+}
+
+// Record is a single start/stop event, the archive's unit of storage
+type Record struct {
+	Identifier string
+	Action     string
+	At         time.Time
+}
+
+// Write streams records into path as zstd frames of about frameSize
+// uncompressed bytes each, followed by a
+// trailing index. records must already be sorted by At.
+func Write(path string, records []Record) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return err
+	}
+	defer encoder.Close()
+
+	var index []frameEntry
+	var offset int64
+
+	for i := 0; i < len(records); {
+		var raw bytes.Buffer
+		rowWriter := csv.NewWriter(&raw)
+
+		var first, last time.Time
+		j := i
+		for ; j < len(records) && raw.Len() < frameSize; j++ {
+			record := records[j]
+			if j == i {
+				first = record.At
+			}
+			last = record.At
+
+			row := []string{record.Identifier, record.Action, record.At.Format(time.RFC3339)}
+			if err := rowWriter.Write(row); err != nil {
+				return err
+			}
+		}
+		rowWriter.Flush()
+		if err := rowWriter.Error(); err != nil {
+			return err
+		}
+
+		compressed := encoder.EncodeAll(raw.Bytes(), nil)
+		n, err := file.Write(compressed)
+		if err != nil {
+			return err
+		}
+
+		index = append(index, frameEntry{Offset: offset, Length: int64(n), First: first, Last: last})
+		offset += int64(n)
+		i = j
+	}
+
+	trailer, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(trailer); err != nil {
+		return err
+	}
+
+	var lengthBuf [8]byte
+	binary.LittleEndian.PutUint64(lengthBuf[:], uint64(len(trailer)))
+	_, err = file.Write(lengthBuf[:])
+	return err
+}
+
+// Archive gives range-scan access to a compacted history file
+type Archive struct {
+	path  string
+	index []frameEntry
+}
+
+// Open reads path's trailing index. Frame data is only decompressed once
+// RangeScan is called, and only for the frames it actually needs.
+func Open(path string) (*Archive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var lengthBuf [8]byte
+	if _, err := file.ReadAt(lengthBuf[:], stat.Size()-int64(len(lengthBuf))); err != nil {
+		return nil, err
+	}
+	length := binary.LittleEndian.Uint64(lengthBuf[:])
+
+	trailer := make([]byte, length)
+	if _, err := file.ReadAt(trailer, stat.Size()-int64(len(lengthBuf))-int64(length)); err != nil {
+		return nil, err
+	}
+
+	var index []frameEntry
+	if err := json.Unmarshal(trailer, &index); err != nil {
+		return nil, err
+	}
+
+	return &Archive{path: path, index: index}, nil
+}
+
+// RangeScan decompresses only the frames intersecting [from, to] and calls
+// fn for every record within that range, in file order.
+func (archive *Archive) RangeScan(from, to time.Time, fn func(Record)) error {
+	file, err := os.Open(archive.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return err
+	}
+	defer decoder.Close()
+
+	// Frames are appended in chronological order, so the index is sorted
+	// by Last; binary search for the first frame that could hold `from`.
+	start := sort.Search(len(archive.index), func(i int) bool {
+		return !archive.index[i].Last.Before(from)
+	})
+
+	for _, entry := range archive.index[start:] {
+		if entry.First.After(to) {
+			break
+		}
+
+		compressed := make([]byte, entry.Length)
+		if _, err := file.ReadAt(compressed, entry.Offset); err != nil {
+			return err
+		}
+
+		raw, err := decoder.DecodeAll(compressed, nil)
+		if err != nil {
+			return err
+		}
+
+		rows, err := csv.NewReader(bytes.NewReader(raw)).ReadAll()
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if len(row) < 3 {
+				continue
+			}
+			at, err := time.Parse(time.RFC3339, row[2])
+			if err != nil || at.Before(from) || at.After(to) {
+				continue
+			}
+			fn(Record{Identifier: row[0], Action: row[1], At: at})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli"
+
+	"github.com/timsolov/golog/archive"
+)
+
+const archiveDir = "~/.golog.archive"
+
+// Compact rolls start/stop events older than the configured retention
+// window out of the hot CSV file into per-month zstd archives under
+// ~/.golog.archive, leaving only recent history for fast status/list.
+func Compact(context *cli.Context) error {
+	config := mustLoadConfig(configPath)
+
+	retention, err := parseRetention(config.Retention)
+	if err != nil {
+		return err
+	}
+	if retention == 0 {
+		return fmt.Errorf("no retention configured (set \"retention\" in %s)", configFile)
+	}
+
+	cutoff := time.Now().Add(-retention)
+
+	archivePath, err := homedir.Expand(archiveDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(archivePath, 0755); err != nil {
+		return err
+	}
+
+	var archived, remaining int
+
+	err = repository.withLock(func(tasks Tasks) (Tasks, error) {
+		var kept Tasks
+		byMonth := map[string][]archive.Record{}
+
+		for _, task := range tasks.Items {
+			at := parseTime(task.getAt())
+			if at.After(cutoff) {
+				kept.Items = append(kept.Items, task)
+				continue
+			}
+
+			month := at.Format("2006-01")
+			byMonth[month] = append(byMonth[month], archive.Record{
+				Identifier: task.Identifier,
+				Action:     task.Action,
+				At:         at,
+			})
+		}
+
+		for month, records := range byMonth {
+			path := filepath.Join(archivePath, month+".zst")
+
+			if existing, err := archive.Open(path); err == nil {
+				existing.RangeScan(time.Time{}, time.Now(), func(record archive.Record) {
+					records = append(records, record)
+				})
+			}
+			// archive.Write requires records sorted by At for RangeScan's
+			// binary search over frame Last to work, regardless of whether
+			// this run merged in an existing archive or is writing a fresh
+			// one.
+			sort.Slice(records, func(i, j int) bool { return records[i].At.Before(records[j].At) })
+
+			if err := archive.Write(path, records); err != nil {
+				return Tasks{}, err
+			}
+		}
+
+		archived, remaining = len(tasks.Items)-len(kept.Items), len(kept.Items)
+		return kept, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Archived %d entries, kept %d\n", archived, remaining)
+	return nil
+}
+
+// parseRetention parses a retention window such as "90d" or any duration
+// string time.ParseDuration accepts ("2160h").
+func parseRetention(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid retention %q: %v", value, err)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// archivedTasks scans ~/.golog.archive for monthly files intersecting
+// filter's since/until bounds and returns the matching entries, hydrated
+// the same way a live repository load would be. It's a no-op if filter
+// doesn't constrain anything, since that would mean decompressing every
+// archive just to return everything in it.
+func archivedTasks(filter Filter) (tasks Tasks, err error) {
+	if filter.Since == "" && filter.Until == "" && filter.Identifier == "" && filter.Project == "" && filter.Tag == "" {
+		return
+	}
+
+	archivePath, err := homedir.Expand(archiveDir)
+	if err != nil {
+		return tasks, err
+	}
+
+	entries, err := ioutil.ReadDir(archivePath)
+	if os.IsNotExist(err) {
+		return tasks, nil
+	}
+	if err != nil {
+		return tasks, err
+	}
+
+	since := time.Time{}
+	if filter.Since != "" {
+		since = parseTime(filter.Since)
+	}
+	until := time.Now()
+	if filter.Until != "" {
+		until = parseTime(filter.Until)
+	}
+
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".zst" {
+			continue
+		}
+
+		monthArchive, err := archive.Open(filepath.Join(archivePath, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		scanErr := monthArchive.RangeScan(since, until, func(record archive.Record) {
+			task := Task{Identifier: record.Identifier, Action: record.Action, At: record.At.Format(time.RFC3339)}
+			task.parseMeta()
+			if filter.Identifier != "" && task.getIdentifier() != filter.Identifier {
+				return
+			}
+			if filter.Project != "" && task.Project != filter.Project {
+				return
+			}
+			if filter.Tag != "" && !hasTag(task.Tags, filter.Tag) {
+				return
+			}
+			tasks.Items = append(tasks.Items, task)
+		})
+		if scanErr != nil {
+			return tasks, scanErr
+		}
+	}
+
+	return
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const configFile = "~/.golog.yaml"
+
+// Config holds the user-facing settings read from ~/.golog.yaml
+type Config struct {
+	Backend string `yaml:"backend"`
+
+	// IdleTimeout, when set, auto-stops the running task once the daemon
+	// sees this much input-idle time (e.g. "15m"). Empty disables it.
+	IdleTimeout string `yaml:"idle_timeout"`
+	// Schedule maps cron expressions to an action the daemon runs at that
+	// time, e.g. "0 18 * * 1-5": "stop" or "0 9 * * 1-5": "start work".
+	Schedule map[string]string `yaml:"schedule"`
+
+	// Retention is how long to keep events in the hot CSV file before
+	// `golog compact` rolls them into a monthly archive, e.g. "90d".
+	Retention string `yaml:"retention"`
+}
+
+// loadConfig reads ~/.golog.yaml, if present. A missing file is not an
+// error: it just means every setting falls back to its default.
+func loadConfig(path string) (Config, error) {
+	var config Config
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	err = yaml.Unmarshal(data, &config)
+	return config, err
+}
+
+// newRepository picks a TaskRepository implementation. GOLOG_BACKEND, when
+// set, takes precedence over the "backend" key in ~/.golog.yaml; the
+// default remains the plain CSV file so existing installs keep working.
+func newRepository(config Config, csvPath string) TaskRepository {
+	backend := strings.ToLower(strings.TrimSpace(config.Backend))
+	if env := os.Getenv("GOLOG_BACKEND"); env != "" {
+		backend = strings.ToLower(strings.TrimSpace(env))
+	}
+
+	dir := filepath.Dir(csvPath)
+
+	switch backend {
+	case "sqlite", "sqlite3":
+		return &TaskSQLiteRepository{Path: filepath.Join(dir, ".golog.sqlite3")}
+	case "bolt", "boltdb":
+		return &TaskBoltRepository{Path: filepath.Join(dir, ".golog.bolt")}
+	default:
+		return TaskCsvRepository{Path: csvPath}
+	}
+}
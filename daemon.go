@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/robfig/cron/v3"
+	"github.com/urfave/cli"
+
+	"github.com/timsolov/golog/idle"
+)
+
+const defaultPidfile = "~/.golog.pid"
+
+// Daemon runs the idle-detection and cron scheduler loop in the
+// foreground until it's stopped (SIGTERM/SIGINT or `golog daemon stop`).
+// The caller backgrounds it, e.g. via the shell's `&` or a process
+// supervisor.
+func Daemon(context *cli.Context) error {
+	pidfile := pidfilePath(context)
+
+	if running, pid := daemonRunning(pidfile); running {
+		return fmt.Errorf("daemon already running (pid %d)", pid)
+	}
+
+	if err := ioutil.WriteFile(pidfile, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		return err
+	}
+	defer os.Remove(pidfile)
+
+	config := mustLoadConfig(configPath)
+
+	// a zero idleTimeout (unset or invalid) disables idle-based auto-stop
+	idleTimeout, _ := time.ParseDuration(config.IdleTimeout)
+
+	scheduler := cron.New()
+	for spec, action := range config.Schedule {
+		action := action
+		if _, err := scheduler.AddFunc(spec, func() { runScheduledAction(action) }); err != nil {
+			return fmt.Errorf("invalid schedule %q: %v", spec, err)
+		}
+	}
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C:
+			if idleTimeout == 0 {
+				continue
+			}
+			since, err := idle.Seconds()
+			if err == nil && since >= idleTimeout {
+				StopAll()
+			}
+		}
+	}
+}
+
+// DaemonStatus reports whether a daemon for this pidfile is running
+func DaemonStatus(context *cli.Context) error {
+	if running, pid := daemonRunning(pidfilePath(context)); running {
+		fmt.Println("daemon running (pid", pid, ")")
+	} else {
+		fmt.Println("daemon not running")
+	}
+	return nil
+}
+
+// DaemonStop signals a running daemon to shut down
+func DaemonStop(context *cli.Context) error {
+	pidfile := pidfilePath(context)
+
+	running, pid := daemonRunning(pidfile)
+	if !running {
+		return errors.New("daemon not running")
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return err
+	}
+
+	fmt.Println("daemon stopped")
+	return nil
+}
+
+// runScheduledAction runs a single ~/.golog.yaml schedule action, e.g.
+// "stop" or "start work"
+func runScheduledAction(action string) {
+	fields := strings.Fields(action)
+	if len(fields) == 0 {
+		return
+	}
+
+	var err error
+	switch fields[0] {
+	case "stop":
+		err = StopAll()
+	case "start":
+		if len(fields) < 2 {
+			err = errors.New("schedule: start requires a task identifier")
+		} else {
+			err = startTask(fields[1])
+		}
+	default:
+		err = fmt.Errorf("schedule: unknown action %q", fields[0])
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "daemon:", err)
+	}
+}
+
+// pidfilePath resolves the --pidfile flag (or its default) to an absolute path
+func pidfilePath(context *cli.Context) string {
+	path := context.String("pidfile")
+	if path == "" {
+		path = defaultPidfile
+	}
+
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return path
+	}
+	return expanded
+}
+
+// daemonRunning checks pidfile for a pid and whether a process with that
+// pid is still alive
+func daemonRunning(pidfile string) (bool, int) {
+	data, err := ioutil.ReadFile(pidfile)
+	if err != nil {
+		return false, 0
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return false, 0
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false, 0
+	}
+
+	// FindProcess always succeeds on Unix; signal 0 is the standard way to
+	// probe whether a pid is still alive without affecting it.
+	if err := process.Signal(syscall.Signal(0)); err != nil {
+		return false, 0
+	}
+
+	return true, pid
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/timsolov/golog/export"
+	"github.com/urfave/cli"
+)
+
+// Export renders tracked intervals in a machine-readable format
+// (json, csv or ics) to stdout
+func Export(context *cli.Context) error {
+	format := context.String("format")
+	exporter, ok := export.Get(format)
+	if !ok {
+		return fmt.Errorf("unknown --format %q (want json, csv or ics)", format)
+	}
+
+	tasks, err := repository.load()
+	if err != nil {
+		return err
+	}
+
+	return exporter.Export(os.Stdout, intervalsFor(tasks))
+}
+
+// intervalsFor pairs up tasks' start/stop events per identifier, the same
+// way TrackingToSeconds does, but keeps the timestamps instead of
+// collapsing them into a duration.
+func intervalsFor(tasks Tasks) (intervals []export.Interval) {
+	for _, identifier := range tasks.uniqueIdentifiers() {
+		nextAction := start
+		var startTime time.Time
+
+		for _, task := range tasks.getByIdentifier(identifier).Items {
+			if task.getAction() == start && nextAction == start {
+				nextAction = stop
+				startTime = parseTime(task.getAt())
+			}
+			if task.getAction() == stop && nextAction == stop {
+				nextAction = start
+				intervals = append(intervals, export.Interval{
+					Identifier: identifier,
+					Start:      startTime,
+					Stop:       parseTime(task.getAt()),
+				})
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// CSVExporter renders intervals as a CSV report with a header row. This is
+// distinct from golog's internal append-only storage format: one row per
+// tracked interval rather than one row per start/stop event.
+type CSVExporter struct{}
+
+// Export writes intervals to w as CSV
+func (CSVExporter) Export(w io.Writer, intervals []Interval) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"identifier", "start", "stop", "duration_seconds"}); err != nil {
+		return err
+	}
+
+	for _, interval := range intervals {
+		row := []string{
+			interval.Identifier,
+			interval.Start.Format(time.RFC3339),
+			interval.Stop.Format(time.RFC3339),
+			strconv.Itoa(int(interval.Stop.Sub(interval.Start).Seconds())),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return writer.Error()
+}
+
+func init() {
+	Register("csv", CSVExporter{})
+}
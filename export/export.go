@@ -0,0 +1,37 @@
+// Package export renders tracked time intervals into machine-readable
+// formats (json, csv, ics, ...). Formats register themselves by name so
+// callers can look one up without the package knowing about every format
+// up front.
+package export
+
+import (
+	"io"
+	"time"
+)
+
+// Interval is a single completed start/stop pair for an identifier, the
+// unit every Exporter renders.
+type Interval struct {
+	Identifier string
+	Start      time.Time
+	Stop       time.Time
+}
+
+// Exporter renders intervals to w in a specific format.
+type Exporter interface {
+	Export(w io.Writer, intervals []Interval) error
+}
+
+var exporters = map[string]Exporter{}
+
+// Register makes an Exporter available under name (e.g. "json", "csv", "ics").
+// Formats call this from an init() function.
+func Register(name string, exporter Exporter) {
+	exporters[name] = exporter
+}
+
+// Get looks up a previously registered Exporter by name.
+func Get(name string) (Exporter, bool) {
+	exporter, ok := exporters[name]
+	return exporter, ok
+}
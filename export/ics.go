@@ -0,0 +1,58 @@
+package export
+
+import (
+	"fmt"
+	"io"
+)
+
+// icsTimeFormat is the RFC 5545 "form 2" UTC date-time format
+const icsTimeFormat = "20060102T150405Z"
+
+// ICSExporter renders intervals as an RFC 5545 iCalendar, one VEVENT per
+// start/stop pair
+type ICSExporter struct{}
+
+// Export writes intervals to w as an iCalendar document
+func (ICSExporter) Export(w io.Writer, intervals []Interval) error {
+	line := func(format string, args ...interface{}) error {
+		_, err := fmt.Fprintf(w, format+"\r\n", args...)
+		return err
+	}
+
+	if err := line("BEGIN:VCALENDAR"); err != nil {
+		return err
+	}
+	if err := line("VERSION:2.0"); err != nil {
+		return err
+	}
+	if err := line("PRODID:-//golog//export//EN"); err != nil {
+		return err
+	}
+
+	for i, interval := range intervals {
+		if err := line("BEGIN:VEVENT"); err != nil {
+			return err
+		}
+		if err := line("UID:golog-%d@golog", i); err != nil {
+			return err
+		}
+		if err := line("SUMMARY:%s", interval.Identifier); err != nil {
+			return err
+		}
+		if err := line("DTSTART:%s", interval.Start.UTC().Format(icsTimeFormat)); err != nil {
+			return err
+		}
+		if err := line("DTEND:%s", interval.Stop.UTC().Format(icsTimeFormat)); err != nil {
+			return err
+		}
+		if err := line("END:VEVENT"); err != nil {
+			return err
+		}
+	}
+
+	return line("END:VCALENDAR")
+}
+
+func init() {
+	Register("ics", ICSExporter{})
+}
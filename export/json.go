@@ -0,0 +1,23 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONExporter renders intervals as a JSON array
+type JSONExporter struct{}
+
+// Export writes intervals to w as an indented JSON array
+func (JSONExporter) Export(w io.Writer, intervals []Interval) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if intervals == nil {
+		intervals = []Interval{}
+	}
+	return encoder.Encode(intervals)
+}
+
+func init() {
+	Register("json", JSONExporter{})
+}
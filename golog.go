@@ -5,18 +5,29 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/urfave/cli"
 )
 
-const alphanumericRegex = "^[a-zA-Z0-9_-]*$"
+const alphanumericRegex = "^[a-zA-Z0-9_/+-]*$"
 const dbFile = "~/.golog"
 
 var dbPath, _ = homedir.Expand(dbFile)
-var repository = TaskCsvRepository{Path: dbPath}
+var configPath, _ = homedir.Expand(configFile)
+var repository = newRepository(mustLoadConfig(configPath), dbPath)
 var transformer = Transformer{}
+
+func mustLoadConfig(path string) Config {
+	config, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return config
+}
+
 var commands = []cli.Command{
 	{
 		Name:         "start",
@@ -48,13 +59,81 @@ var commands = []cli.Command{
 		Name:    "list",
 		Aliases: []string{"l"},
 		Usage:   "List all tasks",
-		Action:  List,
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "tag", Usage: "only show tasks carrying this tag"},
+			cli.StringFlag{Name: "project", Usage: "only show tasks under this project"},
+			cli.StringFlag{Name: "since", Usage: `only show tasks tracked since this date (YYYY-MM-DD or "today")`},
+			cli.StringFlag{Name: "until", Usage: `only show tasks tracked until this date (YYYY-MM-DD or "today")`},
+		},
+		Action: List,
+	},
+	{
+		Name:  "report",
+		Usage: "Report aggregated tracked time, grouped by project, tag, day or week",
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "group-by", Value: "project", Usage: "project, tag, day or week"},
+		},
+		Action: Report,
+	},
+	{
+		Name:  "export",
+		Usage: "Export tracked intervals as json, csv or ics",
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "format", Value: "json", Usage: "json, csv or ics"},
+		},
+		Action: Export,
+	},
+	{
+		Name:   "migrate",
+		Usage:  "Migrate tasks from the CSV file to the configured backend",
+		Action: Migrate,
+	},
+	{
+		Name:  "daemon",
+		Usage: "Run the idle-detection and cron scheduler daemon in the foreground",
+		Flags: []cli.Flag{
+			cli.StringFlag{Name: "pidfile", Value: defaultPidfile, Usage: "path to the daemon pidfile"},
+		},
+		Action: Daemon,
+		Subcommands: []cli.Command{
+			{
+				Name:  "status",
+				Usage: "Show whether the daemon is running",
+				Flags: []cli.Flag{
+					cli.StringFlag{Name: "pidfile", Value: defaultPidfile, Usage: "path to the daemon pidfile"},
+				},
+				Action: DaemonStatus,
+			},
+			{
+				Name:  "stop",
+				Usage: "Stop the running daemon",
+				Flags: []cli.Flag{
+					cli.StringFlag{Name: "pidfile", Value: defaultPidfile, Usage: "path to the daemon pidfile"},
+				},
+				Action: DaemonStop,
+			},
+		},
+	},
+	{
+		Name:   "compact",
+		Usage:  "Roll events older than the configured retention window into monthly archives",
+		Action: Compact,
+	},
+	{
+		Name:   "repair",
+		Usage:  "Scan the csv file for malformed or duplicate rows and rewrite it",
+		Action: Repair,
 	},
 }
 
 // Start a given task
 func Start(context *cli.Context) error {
-	identifier := context.Args().First()
+	return startTask(context.Args().First())
+}
+
+// startTask is the cli.Context-free action layer behind Start, so other
+// callers (the daemon's cron scheduler) can trigger it directly.
+func startTask(identifier string) error {
 	if !IsValidIdentifier(identifier) {
 		return invalidIdentifier(identifier)
 	}
@@ -81,8 +160,12 @@ func Start(context *cli.Context) error {
 
 // Stop a given task
 func Stop(context *cli.Context) error {
-	identifier := context.Args().First()
+	return stopTask(context.Args().First())
+}
 
+// stopTask is the cli.Context-free action layer behind Stop, so other
+// callers (the daemon's cron scheduler) can trigger it directly.
+func stopTask(identifier string) error {
 	if len(identifier) == 0 {
 		// stop all active tasks
 		StopAll()
@@ -108,38 +191,45 @@ func Status(context *cli.Context) error {
 		return invalidIdentifier(identifier)
 	}
 
-	tasks, err := repository.load()
+	filter := Filter{Identifier: identifier}
+
+	tasks, err := repository.query(filter)
 	if err != nil {
 		return err
 	}
-	transformer.LoadedTasks = tasks.getByIdentifier(identifier)
+
+	archived, err := archivedTasks(filter)
+	if err != nil {
+		return err
+	}
+	tasks.Items = append(archived.Items, tasks.Items...)
+
+	transformer.LoadedTasks = tasks
 	tasksTimes, _ := transformer.Transform()
 	fmt.Println(tasksTimes[identifier])
 	return nil
 }
 
-// List lists all tasks
+// List lists all tasks, optionally narrowed down by --tag, --project,
+// --since and --until
 func List(context *cli.Context) error {
-	var err error
-	transformer.LoadedTasks, err = repository.load()
+	filter, err := filterFromContext(context)
 	if err != nil {
 		return err
 	}
 
-	var uitems []string
-	for _, task := range transformer.LoadedTasks.Items {
-		unique := true
-		for _, u := range uitems {
-			if u == task.getIdentifier() {
-				unique = false
-				break
-			}
-		}
-		if unique {
-			uitems = append(uitems, task.getIdentifier())
-		}
+	transformer.LoadedTasks, err = repository.query(filter)
+	if err != nil {
+		return err
 	}
 
+	archived, err := archivedTasks(filter)
+	if err != nil {
+		return err
+	}
+	transformer.LoadedTasks.Items = append(archived.Items, transformer.LoadedTasks.Items...)
+
+	uitems := transformer.LoadedTasks.uniqueIdentifiers()
 	list, total := transformer.Transform()
 
 	for _, identifier := range uitems {
@@ -155,6 +245,88 @@ func List(context *cli.Context) error {
 	return nil
 }
 
+// Report prints tracked time aggregated by --group-by (project, tag, day
+// or week) instead of per identifier
+func Report(context *cli.Context) error {
+	groupBy := GroupBy(context.String("group-by"))
+	switch groupBy {
+	case GroupByProject, GroupByTag, GroupByDay, GroupByWeek:
+	default:
+		return fmt.Errorf("unknown --group-by %q (want project, tag, day or week)", groupBy)
+	}
+
+	var err error
+	transformer.LoadedTasks, err = repository.load()
+	if err != nil {
+		return err
+	}
+
+	grouped, total := transformer.TransformGroupedHuman(groupBy)
+
+	keys := make([]string, 0, len(grouped))
+	for key := range grouped {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Println(grouped[key])
+	}
+
+	if len(keys) > 0 {
+		fmt.Println()
+		fmt.Println("Total: ", total)
+	} else {
+		fmt.Println("Time didn't tracked")
+	}
+	return nil
+}
+
+// filterFromContext builds a Filter from the --tag/--project/--since/--until
+// flags. context may be nil (golog run with no arguments at all).
+func filterFromContext(context *cli.Context) (filter Filter, err error) {
+	if context == nil {
+		return
+	}
+
+	filter.Project = context.String("project")
+	filter.Tag = context.String("tag")
+
+	if filter.Since, err = parseDateBoundary(context.String("since"), false); err != nil {
+		return
+	}
+	if filter.Until, err = parseDateBoundary(context.String("until"), true); err != nil {
+		return
+	}
+
+	return
+}
+
+// parseDateBoundary turns a --since/--until value ("today" or YYYY-MM-DD)
+// into an RFC3339 timestamp comparable against Task.At. An empty value
+// means "no bound". endOfDay shifts the boundary to the last instant of
+// that day, so --until stays inclusive of the given date.
+func parseDateBoundary(value string, endOfDay bool) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	if value == "today" {
+		value = time.Now().Format("2006-01-02")
+	}
+
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q: %v", value, err)
+	}
+
+	if endOfDay {
+		date = date.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return date.Format(time.RFC3339), nil
+}
+
 // ActiveTasks active tasks list
 func ActiveTasks() (list []string, err error) {
 
@@ -0,0 +1,15 @@
+// Package idle reports how long the user has left the keyboard/mouse
+// untouched, so the daemon can auto-stop the running task. The actual
+// detection is OS-specific and lives behind build tags; platforms with no
+// implementation fall back to idle_other.go.
+package idle
+
+import "time"
+
+// Seconds returns how long the system has seen no keyboard/mouse input.
+// It returns an error on platforms with no idle-detection support, which
+// callers should treat as "idle detection unavailable" rather than a
+// fatal condition.
+func Seconds() (time.Duration, error) {
+	return seconds()
+}
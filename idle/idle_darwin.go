@@ -0,0 +1,21 @@
+// +build darwin
+
+package idle
+
+/*
+#cgo LDFLAGS: -framework CoreGraphics
+#include <CoreGraphics/CoreGraphics.h>
+*/
+import "C"
+
+import "time"
+
+// seconds asks CoreGraphics how long it's been since the last keyboard or
+// mouse event, combined across every login session.
+func seconds() (time.Duration, error) {
+	idle := C.CGEventSourceSecondsSinceLastEventType(
+		C.kCGEventSourceStateCombinedSessionState,
+		C.kCGAnyInputEventType,
+	)
+	return time.Duration(float64(idle) * float64(time.Second)), nil
+}
@@ -0,0 +1,35 @@
+// +build linux
+
+package idle
+
+/*
+#cgo LDFLAGS: -lX11 -lXss
+#include <X11/Xlib.h>
+#include <X11/extensions/scrnsaver.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+)
+
+// seconds asks the X11 XScreenSaver extension how long the display has
+// been idle. It fails on Wayland compositors that don't expose an X11
+// root display, in which case callers fall back to never auto-stopping.
+func seconds() (time.Duration, error) {
+	display := C.XOpenDisplay(nil)
+	if display == nil {
+		return 0, errors.New("idle: cannot open X11 display")
+	}
+	defer C.XCloseDisplay(display)
+
+	info := C.XScreenSaverAllocInfo()
+	defer C.XFree(unsafe.Pointer(info))
+
+	root := C.XDefaultRootWindow(display)
+	C.XScreenSaverQueryInfo(display, root, info)
+
+	return time.Duration(info.idle) * time.Millisecond, nil
+}
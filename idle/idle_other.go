@@ -0,0 +1,14 @@
+// +build !linux,!darwin,!windows
+
+package idle
+
+import (
+	"errors"
+	"time"
+)
+
+// seconds is the fallback for platforms with no idle-detection support: it
+// always errors, which tells the daemon to skip idle-based auto-stop.
+func seconds() (time.Duration, error) {
+	return 0, errors.New("idle: not supported on this platform")
+}
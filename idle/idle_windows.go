@@ -0,0 +1,36 @@
+// +build windows
+
+package idle
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+type lastInputInfo struct {
+	cbSize uint32
+	dwTime uint32
+}
+
+var (
+	user32           = syscall.NewLazyDLL("user32.dll")
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procGetLastInput = user32.NewProc("GetLastInputInfo")
+	procGetTickCount = kernel32.NewProc("GetTickCount")
+)
+
+// seconds asks Windows for the tick count of the last input event via
+// GetLastInputInfo and compares it against the current tick count.
+func seconds() (time.Duration, error) {
+	info := lastInputInfo{cbSize: uint32(unsafe.Sizeof(lastInputInfo{}))}
+
+	ret, _, err := procGetLastInput.Call(uintptr(unsafe.Pointer(&info)))
+	if ret == 0 {
+		return 0, err
+	}
+
+	tick, _, _ := procGetTickCount.Call()
+
+	return time.Duration(uint32(tick)-info.dwTime) * time.Millisecond, nil
+}
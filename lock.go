@@ -0,0 +1,32 @@
+package main
+
+import "os"
+
+// fileLock is a cross-platform advisory lock held on a separate ".lock"
+// file alongside the data it guards. acquireLock/releaseLock carry the
+// OS-specific half (flock on Unix, LockFileEx on Windows).
+type fileLock struct {
+	file *os.File
+}
+
+// lockFile opens (creating if needed) and locks path, blocking until the
+// lock is available.
+func lockFile(path string) (*fileLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := acquireLock(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &fileLock{file: file}, nil
+}
+
+// unlock releases the lock and closes the underlying file handle
+func (lock *fileLock) unlock() error {
+	defer lock.file.Close()
+	return releaseLock(lock.file)
+}
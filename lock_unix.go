@@ -0,0 +1,18 @@
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// acquireLock takes an exclusive flock on file, blocking until it's free
+func acquireLock(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+}
+
+// releaseLock releases the flock taken by acquireLock
+func releaseLock(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,53 @@
+// +build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+const lockfileExclusiveLock = 0x2
+
+// acquireLock takes an exclusive LockFileEx lock on file's whole range,
+// blocking until it's free
+func acquireLock(file *os.File) error {
+	var overlapped syscall.Overlapped
+
+	ret, _, err := procLockFileEx.Call(
+		file.Fd(),
+		uintptr(lockfileExclusiveLock),
+		0,
+		^uintptr(0),
+		^uintptr(0),
+		uintptr(unsafe.Pointer(&overlapped)),
+	)
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+// releaseLock releases the lock taken by acquireLock
+func releaseLock(file *os.File) error {
+	var overlapped syscall.Overlapped
+
+	ret, _, err := procUnlockFileEx.Call(
+		file.Fd(),
+		0,
+		^uintptr(0),
+		^uintptr(0),
+		uintptr(unsafe.Pointer(&overlapped)),
+	)
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
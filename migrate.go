@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli"
+)
+
+// Migrate reads every entry out of the existing CSV file and writes it to
+// whichever backend is currently selected (GOLOG_BACKEND / ~/.golog.yaml).
+func Migrate(context *cli.Context) error {
+	if _, stillCsv := repository.(TaskCsvRepository); stillCsv {
+		return errors.New("migrate needs a destination backend configured (set \"backend\" in " + configFile + "); the configured backend is still csv, same as the source")
+	}
+
+	source := TaskCsvRepository{Path: dbPath}
+
+	tasks, err := source.load()
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks.Items {
+		if err := repository.save(task); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Migrated %d entries\n", len(tasks.Items))
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+)
+
+// Repair rewrites the CSV file with malformed and duplicate-consecutive
+// rows removed. It only supports the csv backend; the sqlite and boltdb
+// stores are not scanned.
+func Repair(context *cli.Context) error {
+	csvRepository, ok := repository.(TaskCsvRepository)
+	if !ok {
+		return fmt.Errorf("repair only supports the csv backend")
+	}
+
+	var dropped, orphanStarts, kept int
+
+	err := csvRepository.withLock(func(tasks Tasks) (Tasks, error) {
+		repaired, d, o := repairTasks(tasks)
+		dropped, orphanStarts, kept = d, o, len(repaired.Items)
+		return repaired, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Kept %d entries, dropped %d malformed/duplicate rows, %d task(s) left running\n",
+		kept, dropped, orphanStarts)
+	return nil
+}
+
+// repairTasks drops rows with no identifier or an unrecognized action, and
+// duplicate consecutive states (e.g. two starts in a row for the same
+// identifier with no stop in between). A trailing start with no stop is
+// left in place and only reported: that's an active task, not corruption.
+func repairTasks(tasks Tasks) (repaired Tasks, dropped int, orphanStarts int) {
+	lastAction := map[string]string{}
+
+	for _, task := range tasks.Items {
+		if task.Identifier == "" || (task.Action != "start" && task.Action != "stop") {
+			dropped++
+			continue
+		}
+
+		if lastAction[task.Identifier] == task.Action {
+			dropped++
+			continue
+		}
+
+		lastAction[task.Identifier] = task.Action
+		repaired.Items = append(repaired.Items, task)
+	}
+
+	for _, action := range lastAction {
+		if action == "start" {
+			orphanStarts++
+		}
+	}
+
+	return
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Filter narrows down a query to the repository. A zero-value field means
+// "don't filter on this".
+type Filter struct {
+	Identifier string
+	Project    string
+	Tag        string
+	Since      string
+	Until      string
+}
+
+// TaskRepository is the storage contract every backend (csv, sqlite,
+// boltdb, ...) has to satisfy.
+type TaskRepository interface {
+	// save appends a single Task entry to the repository
+	save(task Task) error
+	// load returns every Task entry in the repository
+	load() (Tasks, error)
+	// clear wipes the repository
+	clear() error
+	// query returns the Task entries matching filter
+	query(filter Filter) (Tasks, error)
+	// replaceAll atomically replaces the repository's entire contents with
+	// tasks, so unlike clear()-then-save()-in-a-loop a crash partway through
+	// can't leave the repository emptied but only partially repopulated.
+	replaceAll(tasks Tasks) error
+	// withLock runs fn with the repository's current contents and atomically
+	// replaces them with whatever fn returns, all under a single lock or
+	// transaction. compact/repair use this instead of load() followed later
+	// by replaceAll(), which acquired and released the lock independently
+	// and left a window where a concurrent save() landed in between and was
+	// silently overwritten by the stale snapshot.
+	withLock(fn func(Tasks) (Tasks, error)) error
+}
+
+// TaskCsvRepository stores tasks as an append-only CSV file
+type TaskCsvRepository struct {
+	Path string
+}
+
+// lockPath is where the advisory lock guarding repository.Path lives. It's
+// a separate file so readers/writers never have to lock the data file
+// itself open in a mode that would conflict with one another.
+func (repository TaskCsvRepository) lockPath() string {
+	return repository.Path + ".lock"
+}
+
+// save appends a single Task entry to the CSV file. The lock serializes
+// this against every other save/load/clear, csv or daemon-issued, so two
+// golog processes running at once can't interleave partial writes.
+func (repository TaskCsvRepository) save(task Task) error {
+	lock, err := lockFile(repository.lockPath())
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+
+	file, err := os.OpenFile(repository.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	return writer.Write([]string{task.Identifier, task.Action, task.At})
+}
+
+// load reads every row of the CSV file into a Tasks collection
+func (repository TaskCsvRepository) load() (tasks Tasks, err error) {
+	lock, err := lockFile(repository.lockPath())
+	if err != nil {
+		return
+	}
+	defer lock.unlock()
+
+	return repository.loadLocked()
+}
+
+// loadLocked is load()'s body without acquiring the lock itself, so
+// withLock can read the current contents as part of a single critical
+// section that also covers the write.
+func (repository TaskCsvRepository) loadLocked() (tasks Tasks, err error) {
+	file, err := os.Open(repository.Path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// accept rows of any length so short rows are skipped below instead of
+	// failing the whole read
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return
+	}
+
+	for _, row := range rows {
+		if len(row) < 3 {
+			continue
+		}
+		task := Task{Identifier: row[0], Action: row[1], At: row[2]}
+		task.parseMeta()
+		tasks.Items = append(tasks.Items, task)
+	}
+
+	return
+}
+
+// clear wipes the CSV file. It writes the replacement via a temp file plus
+// rename so a reader never observes a half-truncated file.
+func (repository TaskCsvRepository) clear() error {
+	lock, err := lockFile(repository.lockPath())
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+
+	temp, err := ioutil.TempFile(filepath.Dir(repository.Path), ".golog-clear-*")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	if err := temp.Close(); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	return os.Rename(tempPath, repository.Path)
+}
+
+// replaceAll atomically rewrites the CSV file to contain exactly tasks, in
+// file order, via a temp file plus rename. Unlike clear()-then-save()-in-
+// a-loop, a crash partway through leaves either the old file or the new
+// one fully intact, never a half-written mix of the two.
+func (repository TaskCsvRepository) replaceAll(tasks Tasks) error {
+	lock, err := lockFile(repository.lockPath())
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+
+	return repository.replaceAllLocked(tasks)
+}
+
+// replaceAllLocked is replaceAll()'s body without acquiring the lock
+// itself, so withLock can write the replacement as part of a single
+// critical section that also covers the read.
+func (repository TaskCsvRepository) replaceAllLocked(tasks Tasks) error {
+	temp, err := ioutil.TempFile(filepath.Dir(repository.Path), ".golog-replace-*")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+
+	writer := csv.NewWriter(temp)
+	for _, task := range tasks.Items {
+		if err := writer.Write([]string{task.Identifier, task.Action, task.At}); err != nil {
+			temp.Close()
+			os.Remove(tempPath)
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		temp.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	return os.Rename(tempPath, repository.Path)
+}
+
+// withLock holds the advisory lock across the whole read-compute-write
+// cycle, so a concurrent save() from another process can't land between
+// the read and the write and get silently discarded by replaceAllLocked.
+func (repository TaskCsvRepository) withLock(fn func(Tasks) (Tasks, error)) error {
+	lock, err := lockFile(repository.lockPath())
+	if err != nil {
+		return err
+	}
+	defer lock.unlock()
+
+	tasks, err := repository.loadLocked()
+	if err != nil {
+		return err
+	}
+
+	replacement, err := fn(tasks)
+	if err != nil {
+		return err
+	}
+
+	return repository.replaceAllLocked(replacement)
+}
+
+// query loads the whole file and filters it in memory. A CSV file has no
+// index to seek into, so this is the best a flat-file backend can do; the
+// sqlite/boltdb backends implement this with an actual indexed lookup.
+func (repository TaskCsvRepository) query(filter Filter) (Tasks, error) {
+	tasks, err := repository.load()
+	if err != nil {
+		return tasks, err
+	}
+	return filterTasks(tasks, filter), nil
+}
+
+// filterTasks applies filter to tasks in memory, shared by backends that
+// don't have a cheaper way to narrow the result set down.
+func filterTasks(tasks Tasks, filter Filter) (filtered Tasks) {
+	for _, task := range tasks.Items {
+		if filter.Identifier != "" && task.getIdentifier() != filter.Identifier {
+			continue
+		}
+		if filter.Project != "" && task.Project != filter.Project {
+			continue
+		}
+		if filter.Tag != "" && !hasTag(task.Tags, filter.Tag) {
+			continue
+		}
+		if filter.Since != "" && task.getAt() < filter.Since {
+			continue
+		}
+		if filter.Until != "" && task.getAt() > filter.Until {
+			continue
+		}
+		filtered.Items = append(filtered.Items, task)
+	}
+	return
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
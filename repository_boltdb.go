@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var tasksBucket = []byte("tasks")
+
+// identifierIndexBucket and timeIndexBucket map, respectively, identifier
+// and at (an RFC3339 string, so lexicographic order is chronological order)
+// to the id of the matching row in tasksBucket, so query() can seek
+// straight to the matching range instead of scanning every row.
+var identifierIndexBucket = []byte("tasks_by_identifier")
+var timeIndexBucket = []byte("tasks_by_time")
+
+// TaskBoltRepository stores tasks in a BoltDB database, keyed by an
+// auto-incrementing id. identifierIndexBucket and timeIndexBucket are kept
+// alongside it, so a query filtering on Identifier, Since or Until can seek
+// into the matching index instead of walking every row in tasksBucket.
+type TaskBoltRepository struct {
+	Path string
+	db   *bolt.DB
+}
+
+func (repository *TaskBoltRepository) open() (*bolt.DB, error) {
+	if repository.db != nil {
+		return repository.db, nil
+	}
+
+	db, err := bolt.Open(repository.Path, 0644, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{tasksBucket, identifierIndexBucket, timeIndexBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	repository.db = db
+	return db, nil
+}
+
+// indexKey builds a key for identifierIndexBucket/timeIndexBucket: value,
+// then the row's id, so entries sharing the same value (identifier or at)
+// still sort by insertion order and never collide.
+func indexKey(value string, id uint64) []byte {
+	key := append([]byte(value), 0)
+	return append(key, itob(id)...)
+}
+
+// putTask inserts task into tasksBucket and both indexes in lock-step, so
+// the three buckets never drift out of sync with one another.
+func putTask(tx *bolt.Tx, task Task) error {
+	bucket := tx.Bucket(tasksBucket)
+
+	id, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	if err := bucket.Put(itob(id), value); err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(identifierIndexBucket).Put(indexKey(task.Identifier, id), itob(id)); err != nil {
+		return err
+	}
+	return tx.Bucket(timeIndexBucket).Put(indexKey(task.At, id), itob(id))
+}
+
+func (repository *TaskBoltRepository) save(task Task) error {
+	db, err := repository.open()
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		return putTask(tx, task)
+	})
+}
+
+func (repository *TaskBoltRepository) load() (Tasks, error) {
+	return repository.query(Filter{})
+}
+
+func (repository *TaskBoltRepository) clear() error {
+	db, err := repository.open()
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{tasksBucket, identifierIndexBucket, timeIndexBucket} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// replaceAll atomically replaces every row in a single bolt transaction,
+// so a crash partway through leaves either the old rows or the new ones
+// intact, never a half-written mix.
+func (repository *TaskBoltRepository) replaceAll(tasks Tasks) error {
+	db, err := repository.open()
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		return replaceAllBoltTx(tx, tasks)
+	})
+}
+
+// replaceAllBoltTx is replaceAll()'s body against an already-open transaction,
+// so withLock can write the replacement in the same transaction it read
+// the current rows from.
+func replaceAllBoltTx(tx *bolt.Tx, tasks Tasks) error {
+	for _, name := range [][]byte{tasksBucket, identifierIndexBucket, timeIndexBucket} {
+		if err := tx.DeleteBucket(name); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(name); err != nil {
+			return err
+		}
+	}
+
+	for _, task := range tasks.Items {
+		if err := putTask(tx, task); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withLock reads and replaces the repository's contents inside a single
+// bolt transaction. bbolt allows only one write transaction at a time (and
+// holds a file lock across processes while one is open), so a concurrent
+// save() can't land between the read and the write and get silently
+// discarded by replaceAll.
+func (repository *TaskBoltRepository) withLock(fn func(Tasks) (Tasks, error)) error {
+	db, err := repository.open()
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		var tasks Tasks
+		if err := scanAll(tx, Filter{}, &tasks); err != nil {
+			return err
+		}
+
+		replacement, err := fn(tasks)
+		if err != nil {
+			return err
+		}
+
+		return replaceAllBoltTx(tx, replacement)
+	})
+}
+
+// query picks the cheapest available index for filter: an identifier filter
+// seeks straight into identifierIndexBucket, a since/until filter (with no
+// identifier) seeks into timeIndexBucket, and anything else falls back to a
+// full scan of tasksBucket.
+func (repository *TaskBoltRepository) query(filter Filter) (tasks Tasks, err error) {
+	db, err := repository.open()
+	if err != nil {
+		return
+	}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		switch {
+		case filter.Identifier != "":
+			return scanIndex(tx, identifierIndexBucket, []byte(filter.Identifier), []byte(filter.Identifier), filter, &tasks)
+		case filter.Since != "" || filter.Until != "":
+			return scanIndex(tx, timeIndexBucket, []byte(filter.Since), []byte(filter.Until), filter, &tasks)
+		default:
+			return scanAll(tx, filter, &tasks)
+		}
+	})
+
+	return
+}
+
+// scanIndex walks index (identifierIndexBucket or timeIndexBucket) over
+// keys whose value prefix falls in [from, to] (an empty bound means
+// unbounded on that side), resolves each hit back to its row in
+// tasksBucket, and appends it to tasks if it matches the rest of filter.
+func scanIndex(tx *bolt.Tx, index []byte, from, to []byte, filter Filter, tasks *Tasks) error {
+	bucket := tx.Bucket(tasksBucket)
+	cursor := tx.Bucket(index).Cursor()
+
+	var key, id []byte
+	if len(from) > 0 {
+		key, id = cursor.Seek(from)
+	} else {
+		key, id = cursor.First()
+	}
+
+	for ; key != nil; key, id = cursor.Next() {
+		if len(to) > 0 && bytes.Compare(key, append(append([]byte{}, to...), 0xff)) > 0 {
+			break
+		}
+
+		value := bucket.Get(id)
+		if value == nil {
+			continue
+		}
+
+		var task Task
+		if err := json.Unmarshal(value, &task); err != nil {
+			return err
+		}
+		task.parseMeta()
+		if matchesFilter(task, filter) {
+			tasks.Items = append(tasks.Items, task)
+		}
+	}
+
+	return nil
+}
+
+// scanAll walks every row in tasksBucket in insertion order, for filters
+// that don't narrow down to an identifier or a time range.
+func scanAll(tx *bolt.Tx, filter Filter, tasks *Tasks) error {
+	cursor := tx.Bucket(tasksBucket).Cursor()
+	for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+		var task Task
+		if err := json.Unmarshal(value, &task); err != nil {
+			return err
+		}
+		task.parseMeta()
+		if matchesFilter(task, filter) {
+			tasks.Items = append(tasks.Items, task)
+		}
+	}
+	return nil
+}
+
+// matchesFilter applies the parts of filter that the chosen index didn't
+// already narrow down.
+func matchesFilter(task Task, filter Filter) bool {
+	if filter.Identifier != "" && task.getIdentifier() != filter.Identifier {
+		return false
+	}
+	if filter.Project != "" && task.Project != filter.Project {
+		return false
+	}
+	if filter.Tag != "" && !hasTag(task.Tags, filter.Tag) {
+		return false
+	}
+	if filter.Since != "" && task.getAt() < filter.Since {
+		return false
+	}
+	if filter.Until != "" && task.getAt() > filter.Until {
+		return false
+	}
+	return true
+}
+
+func itob(id uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, id)
+	return buf
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestTaskCsvRepositoryConcurrentSave hammers a single TaskCsvRepository
+// from many goroutines at once - the same shape of contention as a daemon
+// and an interactive `golog start`/`stop` racing each other - and checks
+// the resulting file still parses cleanly and accounts for every write.
+// This guards the flock-based locking in repository.go: without it,
+// interleaved writers can corrupt a row or lose one outright.
+func TestTaskCsvRepositoryConcurrentSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog-fuzz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	repository := TaskCsvRepository{Path: filepath.Join(dir, "golog.csv")}
+	if err := ioutil.WriteFile(repository.Path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const goroutines = 20
+	const pairsPerGoroutine = 25
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			identifier := fmt.Sprintf("task-%d", g)
+			for i := 0; i < pairsPerGoroutine; i++ {
+				start := Task{Identifier: identifier, Action: "start", At: fmt.Sprintf("2024-01-01T00:%02d:00Z", i)}
+				if err := repository.save(start); err != nil {
+					t.Errorf("save start: %v", err)
+				}
+
+				stop := Task{Identifier: identifier, Action: "stop", At: fmt.Sprintf("2024-01-01T00:%02d:01Z", i)}
+				if err := repository.save(stop); err != nil {
+					t.Errorf("save stop: %v", err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	tasks, err := repository.load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	wantRows := goroutines * pairsPerGoroutine * 2
+	if len(tasks.Items) != wantRows {
+		t.Fatalf("got %d rows, want %d (rows were lost or corrupted)", len(tasks.Items), wantRows)
+	}
+
+	rowsByIdentifier := map[string]int{}
+	for _, task := range tasks.Items {
+		if task.Action != "start" && task.Action != "stop" {
+			t.Fatalf("malformed row: action %q", task.Action)
+		}
+		rowsByIdentifier[task.Identifier]++
+	}
+
+	transformer := Transformer{LoadedTasks: tasks}
+
+	for g := 0; g < goroutines; g++ {
+		identifier := fmt.Sprintf("task-%d", g)
+
+		if got := rowsByIdentifier[identifier]; got != pairsPerGoroutine*2 {
+			t.Errorf("%s: got %d rows, want %d", identifier, got, pairsPerGoroutine*2)
+		}
+
+		seconds, active := transformer.TrackingToSeconds(identifier)
+		if active {
+			t.Errorf("%s: expected not active", identifier)
+		}
+		if seconds != pairsPerGoroutine {
+			t.Errorf("%s: got %d tracked seconds, want %d", identifier, seconds, pairsPerGoroutine)
+		}
+	}
+}
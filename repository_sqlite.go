@@ -0,0 +1,205 @@
+package main
+
+import (
+	"database/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TaskSQLiteRepository stores tasks in a SQLite database, indexed by
+// identifier and timestamp so status/list/query don't need to rescan
+// everything.
+type TaskSQLiteRepository struct {
+	Path string
+	db   *sql.DB
+}
+
+func (repository *TaskSQLiteRepository) open() (*sql.DB, error) {
+	if repository.db != nil {
+		return repository.db, nil
+	}
+
+	db, err := sql.Open("sqlite3", repository.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS tasks (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			identifier TEXT NOT NULL,
+			action     TEXT NOT NULL,
+			at         TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_tasks_identifier ON tasks (identifier);
+		CREATE INDEX IF NOT EXISTS idx_tasks_at ON tasks (at);
+	`); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	repository.db = db
+	return db, nil
+}
+
+func (repository *TaskSQLiteRepository) save(task Task) error {
+	db, err := repository.open()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`INSERT INTO tasks (identifier, action, at) VALUES (?, ?, ?)`,
+		task.Identifier, task.Action, task.At)
+	return err
+}
+
+func (repository *TaskSQLiteRepository) load() (Tasks, error) {
+	return repository.query(Filter{})
+}
+
+func (repository *TaskSQLiteRepository) clear() error {
+	db, err := repository.open()
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`DELETE FROM tasks`)
+	return err
+}
+
+// replaceAll atomically replaces every row via a single transaction, so a
+// crash partway through leaves either the old rows or the new ones intact.
+func (repository *TaskSQLiteRepository) replaceAll(tasks Tasks) error {
+	db, err := repository.open()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := replaceAllSQLTx(tx, tasks); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// replaceAllSQLTx is replaceAll()'s body against an already-open transaction,
+// so withLock can write the replacement in the same transaction it read
+// the current rows from.
+func replaceAllSQLTx(tx *sql.Tx, tasks Tasks) error {
+	if _, err := tx.Exec(`DELETE FROM tasks`); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO tasks (identifier, action, at) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, task := range tasks.Items {
+		if _, err := stmt.Exec(task.Identifier, task.Action, task.At); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withLock reads and replaces the repository's contents inside a single
+// transaction, so a concurrent save() (a separate connection/transaction)
+// can't land between the read and the write and get silently discarded by
+// replaceAll.
+func (repository *TaskSQLiteRepository) withLock(fn func(Tasks) (Tasks, error)) error {
+	db, err := repository.open()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	tasks, err := queryTx(tx, Filter{})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	replacement, err := fn(tasks)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := replaceAllSQLTx(tx, replacement); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repository *TaskSQLiteRepository) query(filter Filter) (Tasks, error) {
+	db, err := repository.open()
+	if err != nil {
+		return Tasks{}, err
+	}
+	return queryTx(db, filter)
+}
+
+// sqlQueryer is satisfied by both *sql.DB and *sql.Tx, so queryTx can run
+// against a plain connection (query()) or an already-open transaction
+// (withLock()).
+type sqlQueryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func queryTx(db sqlQueryer, filter Filter) (tasks Tasks, err error) {
+	query := `SELECT identifier, action, at FROM tasks WHERE 1 = 1`
+	var args []interface{}
+
+	if filter.Identifier != "" {
+		query += ` AND identifier = ?`
+		args = append(args, filter.Identifier)
+	}
+	if filter.Since != "" {
+		query += ` AND at >= ?`
+		args = append(args, filter.Since)
+	}
+	if filter.Until != "" {
+		query += ` AND at <= ?`
+		args = append(args, filter.Until)
+	}
+	query += ` ORDER BY id ASC`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task Task
+		if err = rows.Scan(&task.Identifier, &task.Action, &task.At); err != nil {
+			return
+		}
+		task.parseMeta()
+		// Project/Tags aren't stored columns, so project/tag filters are
+		// applied here rather than pushed into the WHERE clause above.
+		if filter.Project != "" && task.Project != filter.Project {
+			continue
+		}
+		if filter.Tag != "" && !hasTag(task.Tags, filter.Tag) {
+			continue
+		}
+		tasks.Items = append(tasks.Items, task)
+	}
+
+	return tasks, rows.Err()
+}
@@ -0,0 +1,83 @@
+package main
+
+import "strings"
+
+// Task represents a single start/stop event recorded for an identifier.
+// Project and Tags are derived from Identifier (see splitIdentifier) and
+// populated by each repository as it loads rows, so callers never have to
+// parse the raw identifier themselves.
+type Task struct {
+	Identifier string
+	Action     string
+	At         string
+	Project    string
+	Tags       []string
+}
+
+func (task Task) getIdentifier() string {
+	return task.Identifier
+}
+
+func (task Task) getAction() string {
+	return task.Action
+}
+
+func (task Task) getAt() string {
+	return task.At
+}
+
+// parseMeta fills Project and Tags from Identifier. It must be called once
+// after a Task is loaded or constructed from a raw identifier string.
+func (task *Task) parseMeta() {
+	task.Project, _, task.Tags = splitIdentifier(task.Identifier)
+}
+
+// splitIdentifier breaks a raw "project/name+tag1+tag2" identifier into its
+// project, bare name and tags. Any part missing from raw is returned empty.
+func splitIdentifier(raw string) (project, name string, tags []string) {
+	name = raw
+
+	if idx := strings.IndexByte(name, '/'); idx >= 0 {
+		project = name[:idx]
+		name = name[idx+1:]
+	}
+
+	if idx := strings.IndexByte(name, '+'); idx >= 0 {
+		for _, tag := range strings.Split(name[idx+1:], "+") {
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		name = name[:idx]
+	}
+
+	return
+}
+
+// Tasks is an ordered collection of Task entries
+type Tasks struct {
+	Items []Task
+}
+
+// getByIdentifier filters the collection down to entries matching identifier
+func (tasks Tasks) getByIdentifier(identifier string) (filtered Tasks) {
+	for _, task := range tasks.Items {
+		if task.getIdentifier() == identifier {
+			filtered.Items = append(filtered.Items, task)
+		}
+	}
+	return
+}
+
+// uniqueIdentifiers returns every distinct identifier in the collection, in
+// first-seen order.
+func (tasks Tasks) uniqueIdentifiers() (identifiers []string) {
+	seen := map[string]bool{}
+	for _, task := range tasks.Items {
+		if !seen[task.Identifier] {
+			seen[task.Identifier] = true
+			identifiers = append(identifiers, task.Identifier)
+		}
+	}
+	return
+}
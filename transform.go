@@ -86,6 +86,110 @@ func isActive(nextAction string) bool {
 	return nextAction == stop
 }
 
+// GroupBy enumerates the dimensions a report can be aggregated by
+type GroupBy string
+
+// Supported report groupings
+const (
+	GroupByProject GroupBy = "project"
+	GroupByTag     GroupBy = "tag"
+	GroupByDay     GroupBy = "day"
+	GroupByWeek    GroupBy = "week"
+)
+
+const ungrouped = "(none)"
+
+// TransformGrouped aggregates tracked time by groupBy instead of per
+// identifier, e.g. total time per project, per tag, or per calendar day.
+func (transformer *Transformer) TransformGrouped(groupBy GroupBy) (grouped map[string]int, totalSeconds int) {
+	grouped = map[string]int{}
+
+	for _, identifier := range transformer.LoadedTasks.uniqueIdentifiers() {
+		switch groupBy {
+		case GroupByDay, GroupByWeek:
+			transformer.bucketByTime(identifier, groupBy, grouped)
+		default:
+			seconds, _ := transformer.TrackingToSeconds(identifier)
+			for _, key := range transformer.groupKeys(identifier, groupBy) {
+				grouped[key] += seconds
+			}
+		}
+	}
+
+	for _, seconds := range grouped {
+		totalSeconds += seconds
+	}
+
+	return
+}
+
+// TransformGroupedHuman is the human-readable counterpart to TransformGrouped
+func (transformer *Transformer) TransformGroupedHuman(groupBy GroupBy) (rendered map[string]string, totalTime string) {
+	grouped, totalSeconds := transformer.TransformGrouped(groupBy)
+
+	rendered = map[string]string{}
+	for key, seconds := range grouped {
+		rendered[key] = fmt.Sprintf("%s    %s", transformer.SecondsToHuman(seconds), key)
+	}
+
+	totalTime = transformer.SecondsToHuman(totalSeconds)
+	return
+}
+
+// groupKeys returns the bucket(s) identifier belongs to for the project/tag
+// groupings. A task can carry several tags, so it can land in several
+// buckets under GroupByTag.
+func (transformer *Transformer) groupKeys(identifier string, groupBy GroupBy) []string {
+	project, _, tags := splitIdentifier(identifier)
+
+	switch groupBy {
+	case GroupByProject:
+		if project == "" {
+			return []string{ungrouped}
+		}
+		return []string{project}
+	case GroupByTag:
+		if len(tags) == 0 {
+			return []string{ungrouped}
+		}
+		return tags
+	default:
+		return []string{identifier}
+	}
+}
+
+// bucketByTime walks identifier's start/stop pairs and attributes each
+// interval's duration to the day or ISO week it started in.
+func (transformer *Transformer) bucketByTime(identifier string, groupBy GroupBy, grouped map[string]int) {
+	nextAction := start
+	var startTime time.Time
+
+	tasks := transformer.LoadedTasks.getByIdentifier(identifier)
+	for _, task := range tasks.Items {
+		if task.getAction() == start && nextAction == start {
+			nextAction = stop
+			startTime = parseTime(task.getAt())
+		}
+		if task.getAction() == stop && nextAction == stop {
+			nextAction = start
+			stopTime := parseTime(task.getAt())
+			grouped[bucketKey(startTime, groupBy)] += int(stopTime.Sub(startTime).Seconds())
+		}
+	}
+
+	if isActive(nextAction) {
+		grouped[bucketKey(startTime, groupBy)] += int(time.Since(startTime).Seconds())
+	}
+}
+
+func bucketKey(t time.Time, groupBy GroupBy) string {
+	if groupBy == GroupByWeek {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return t.Format("2006-01-02")
+}
+
 func parseTime(at string) time.Time {
 	then, err := time.Parse(time.RFC3339, at)
 	if err != nil {